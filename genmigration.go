@@ -38,6 +38,21 @@ var {{ migrationName }} = &gormmigrate.Migration{
 			return nil
 		})
 	},
+	Rollback: func(d *gorm.DB) error {
+		commands := []string{
+			// add commands to undo Migrate, if needed
+		}
+		return d.Transaction(func(tx *gorm.DB) error {
+			var err error
+			for _, command := range commands {
+				err = tx.Exec(command).Error
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	},
 }
 `
 
@@ -50,6 +65,10 @@ type MigrationsStore interface {
 type MigrationOption struct {
 	DstFolder string
 	Store     MigrationsStore
+	// Source, when set, overrides Store.Migrations() as the source of
+	// migrations to run. Store is still used for DB() and, for the generate
+	// and plan commands, Models().
+	Source MigrationSource
 }
 
 type MigrationCommand string
@@ -58,7 +77,10 @@ const (
 	CommandPrefix   = "migration"
 	CommandGenerate = "generate"
 	CommandMigrate  = "migrate"
-	// TODO: up and down
+	CommandDown     = "down"
+	CommandTo       = "to"
+	CommandStatus   = "status"
+	CommandPlan     = "plan"
 )
 
 var (
@@ -96,6 +118,10 @@ func ExecuteCommand(args []string) error {
 	if !isInStringArr([]string{
 		CommandGenerate,
 		CommandMigrate,
+		CommandDown,
+		CommandTo,
+		CommandStatus,
+		CommandPlan,
 	}, command) {
 		return ErrInvalidCommand
 	}
@@ -106,49 +132,169 @@ func ExecuteCommand(args []string) error {
 			return errors.New("name of migrations is required")
 		}
 		name := args[1]
+		sqlMode := isInStringArr(args[2:], "--sql")
 		models := migrationOp.Store.Models()
 		return generateMigrations(
 			name,
+			sqlMode,
 			models...,
 		)
 	case CommandMigrate:
 		return MigrateDB()
+	case CommandDown:
+		if len(args) >= 2 {
+			return RollbackToDB(args[1])
+		}
+		return RollbackLastDB()
+	case CommandTo:
+		if len(args) < 2 {
+			return errors.New("target migration id is required")
+		}
+		return MigrateToDB(args[1])
+	case CommandStatus:
+		return printStatus()
+	case CommandPlan:
+		return printPlan()
 	}
 	return nil
 }
 
-func MigrateDB() error {
-	if migrationOp == nil {
-		return ErrNotConfigured
+func printStatus() error {
+	m, err := newMigrate()
+	if err != nil {
+		return err
+	}
+	statuses, err := m.Status()
+	if err != nil {
+		return err
 	}
-	migrations := migrationOp.Store.Migrations()
-	m := New(db, &Options{
+	for _, s := range statuses {
+		switch {
+		case s.Applied && s.AppliedAt != nil:
+			fmt.Printf("[applied] %s (%s)\n", s.ID, s.AppliedAt.Format(time.RFC3339))
+		case s.Applied:
+			fmt.Printf("[applied] %s (applied_at unknown)\n", s.ID)
+		default:
+			fmt.Printf("[pending] %s\n", s.ID)
+		}
+	}
+	return nil
+}
+
+// printPlan captures the SQL AutoMigrate would run against the current
+// schema without writing any migration file, useful for CI diffs.
+func printPlan() error {
+	statements, err := captureAutoMigrateStatements(migrationOp.Store.Models()...)
+	if err != nil {
+		return err
+	}
+	if len(statements) == 0 {
+		fmt.Println("-- no schema changes")
+		return nil
+	}
+	for _, statement := range statements {
+		fmt.Printf("%s;\n", statement)
+	}
+	return nil
+}
+
+func loadMigrations() ([]*Migration, error) {
+	if migrationOp.Source != nil {
+		return migrationOp.Source.Load()
+	}
+	return migrationOp.Store.Migrations(), nil
+}
+
+func newMigrate() (*Migrate, error) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return nil, err
+	}
+	return New(db, &Options{
 		TableName:    "_migrations",
 		IDColumnName: "id",
-	}, migrations)
+	}, migrations), nil
+}
 
+func MigrateDB() error {
+	if migrationOp == nil {
+		return ErrNotConfigured
+	}
+	m, err := newMigrate()
+	if err != nil {
+		return err
+	}
 	return m.Migrate()
 }
 
-func generateMigrations(name string, dst ...interface{}) (err error) {
-	tx := db.Begin()
-	var statements []string
-	err = tx.Callback().Raw().Remove("gorm:raw")
+// MigrateToDB runs all pending migrations up to and including id.
+func MigrateToDB(id string) error {
+	if migrationOp == nil {
+		return ErrNotConfigured
+	}
+	m, err := newMigrate()
 	if err != nil {
 		return err
 	}
-	err = tx.Callback().Raw().Register("gorm:raw", func(tx *gorm.DB) {
-		statements = append(statements, tx.Statement.SQL.String())
-	})
+	return m.MigrateTo(id)
+}
+
+// RollbackLastDB rolls back the last applied migration.
+func RollbackLastDB() error {
+	if migrationOp == nil {
+		return ErrNotConfigured
+	}
+	m, err := newMigrate()
 	if err != nil {
 		return err
 	}
-	err = tx.AutoMigrate(dst...)
+	return m.RollbackLast()
+}
+
+// RollbackToDB rolls back every applied migration up to but excluding id.
+func RollbackToDB(id string) error {
+	if migrationOp == nil {
+		return ErrNotConfigured
+	}
+	m, err := newMigrate()
 	if err != nil {
 		return err
 	}
+	return m.RollbackTo(id)
+}
+
+// captureAutoMigrateStatements runs AutoMigrate against a rolled-back
+// transaction and returns the SQL statements it would have executed,
+// without touching the schema.
+func captureAutoMigrateStatements(dst ...interface{}) ([]string, error) {
+	tx := db.Begin()
+	var statements []string
+	if err := tx.Callback().Raw().Remove("gorm:raw"); err != nil {
+		return nil, err
+	}
+	if err := tx.Callback().Raw().Register("gorm:raw", func(tx *gorm.DB) {
+		statements = append(statements, tx.Statement.SQL.String())
+	}); err != nil {
+		return nil, err
+	}
+	if err := tx.AutoMigrate(dst...); err != nil {
+		return nil, err
+	}
 	tx.Rollback()
 	_ = tx.Callback().Raw().Remove("gorm:raw")
+	return statements, nil
+}
+
+func generateMigrations(name string, sqlMode bool, dst ...interface{}) error {
+	statements, err := captureAutoMigrateStatements(dst...)
+	if err != nil {
+		return err
+	}
+
+	if sqlMode {
+		return writeSQLMigrationFiles(name, statements)
+	}
+
 	commands := []string{}
 	for _, s := range statements {
 		c := fmt.Sprintf("\t\t\t\"%v\",", s)
@@ -173,6 +319,30 @@ func generateMigrations(name string, dst ...interface{}) (err error) {
 	return os.WriteFile(migrationFileName, []byte(content), 0644)
 }
 
+// writeSQLMigrationFiles writes the captured AutoMigrate statements to a
+// pair of plain SQL files instead of a Go migration, so they can be picked
+// up by a FileMigrationSource/EmbedFileMigrationSource without a rebuild.
+func writeSQLMigrationFiles(name string, statements []string) error {
+	timestamp := time.Now().UTC().Format("20060102150405")
+	snakeName := strcase.ToSnake(name)
+	migrationID := fmt.Sprintf("%v_%v", timestamp, snakeName)
+
+	var upContent string
+	if len(statements) > 0 {
+		upContent = strings.Join(statements, ";\n") + ";\n"
+	}
+
+	upFileName := fmt.Sprintf("migrations/%v.up.sql", migrationID)
+	downFileName := fmt.Sprintf("migrations/%v.down.sql", migrationID)
+
+	// nolint: gosec
+	if err := os.WriteFile(upFileName, []byte(upContent), 0644); err != nil {
+		return err
+	}
+	// nolint: gosec
+	return os.WriteFile(downFileName, []byte("-- add statements to undo the matching .up.sql file\n"), 0644)
+}
+
 func isInStringArr(arr []string, s string) bool {
 	for _, v := range arr {
 		if v == s {
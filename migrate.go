@@ -1,10 +1,19 @@
 package gormmigrate
 
 import (
+	"context"
+	"database/sql"
 	"errors"
 	"fmt"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const (
+	appliedAtColumnName = "applied_at"
+	phaseColumnName     = "phase"
 )
 
 var (
@@ -12,6 +21,59 @@ var (
 	ErrMissingID = errors.New("missing ID in migration")
 )
 
+// ErrNoRollback is returned when RollbackLast or RollbackTo reaches a
+// migration that was applied but has no Rollback func defined.
+type ErrNoRollback struct {
+	ID string
+}
+
+func (e ErrNoRollback) Error() string {
+	return fmt.Sprintf("gormmigrate: migration %q has no Rollback func", e.ID)
+}
+
+// ErrUnknownID is returned when RollbackTo is given an id that doesn't match
+// any applied migration.
+type ErrUnknownID struct {
+	ID string
+}
+
+func (e ErrUnknownID) Error() string {
+	return fmt.Sprintf("gormmigrate: no applied migration with ID %q", e.ID)
+}
+
+// ErrDuplicateID is returned when two migrations share the same ID.
+type ErrDuplicateID struct {
+	ID string
+}
+
+func (e ErrDuplicateID) Error() string {
+	return fmt.Sprintf("gormmigrate: duplicate migration ID %q", e.ID)
+}
+
+// ErrOutOfOrderID is returned when migration IDs are not strictly increasing
+// in lexicographic order, which breaks the timestamp-prefixed ID convention
+// this package relies on for rollback ordering.
+var ErrOutOfOrderID = errors.New("gormmigrate: migration IDs are not in strictly increasing order")
+
+// ErrUnknownMigrationInDB is returned when Options.ValidateUnknownMigrations
+// is set and the migrations table references an ID that isn't present in
+// the configured migrations, e.g. after switching branches or deleting a
+// migration file.
+type ErrUnknownMigrationInDB struct {
+	ID string
+}
+
+func (e ErrUnknownMigrationInDB) Error() string {
+	return fmt.Sprintf("gormmigrate: migration %q is recorded in the migrations table but not configured", e.ID)
+}
+
+// ErrTransactionalDDLUnsupported is returned by runMigration when
+// Options.UseTransaction is on but the connected dialect does not support
+// running DDL inside a transaction (e.g. MySQL/MariaDB implicitly commit on
+// DDL). Set Migration.DisableTransaction for the affected migrations or turn
+// Options.UseTransaction off.
+var ErrTransactionalDDLUnsupported = errors.New("gormmigrate: dialect does not support transactional DDL")
+
 // MigrateFunc is the func signature for migrating.
 type MigrateFunc func(*gorm.DB) error
 
@@ -23,6 +85,14 @@ type Migration struct {
 	ID string
 	// Migrate is a function that will br executed while running this migration.
 	Migrate MigrateFunc
+	// Rollback is a function that undoes Migrate. It is optional: migrations
+	// without one can still be applied going forward but cannot be rolled back.
+	Rollback MigrateFunc
+	// DisableTransaction opts this migration out of the transaction wrapping
+	// enabled by Options.UseTransaction. Use it for statements that cannot
+	// run inside a transaction, e.g. Postgres `CREATE INDEX CONCURRENTLY` or
+	// MySQL DDL that implicitly commits.
+	DisableTransaction bool
 }
 
 // Options define options for all migrations.
@@ -31,14 +101,36 @@ type Options struct {
 	TableName string
 	// IDColumnName is the name of column where the migration id will be stored.
 	IDColumnName string
+	// UseTransaction wraps a migration's Migrate call and its tracking-table
+	// insert in a single DB transaction, so a failure leaves the migrations
+	// table consistent with what was actually applied.
+	UseTransaction bool
+	// ValidateUnknownMigrations makes Migrate fail if the migrations table
+	// has an ID that isn't present in the configured migrations.
+	ValidateUnknownMigrations bool
+	// Logger reports the start/end and timing of each migration. Defaults to
+	// the DB connection's own logger.
+	Logger logger.Interface
+	// VersionedSchemaPrefix names the schema that holds the compatibility
+	// views created by MigrateExpand, as "<VersionedSchemaPrefix>_v<Version>".
+	// Defaults to TableName when empty.
+	VersionedSchemaPrefix string
+}
+
+// MigrationStatus describes whether a configured migration has been applied.
+type MigrationStatus struct {
+	ID        string
+	Applied   bool
+	AppliedAt *time.Time
 }
 
 // Migrate represents a collection of all migrations of a database schemas.
 type Migrate struct {
-	db         *gorm.DB
-	options    *Options
-	migrations []*Migration
-	initSchema InitSchemaFunc
+	db                  *gorm.DB
+	options             *Options
+	migrations          []*Migration
+	initSchema          InitSchemaFunc
+	versionedMigrations []*VersionedMigration
 }
 
 // New returns a new Gormigrate.
@@ -58,9 +150,25 @@ func (m *Migrate) InitSchema(initSchema InitSchemaFunc) {
 	m.initSchema = initSchema
 }
 
+// VersionedMigrations sets the expand/contract migrations run by
+// MigrateExpand and MigrateContract.
+func (m *Migrate) VersionedMigrations(migrations []*VersionedMigration) {
+	m.versionedMigrations = migrations
+}
+
 // Migrate executes all migrations that did not run yet.
 func (m *Migrate) Migrate() error {
-	if err := m.createMigrationTableIfNotExists(); err != nil {
+	return m.migrateTo("")
+}
+
+// MigrateTo executes all migrations that did not run yet, stopping after the
+// migration identified by id has run.
+func (m *Migrate) MigrateTo(id string) error {
+	return m.migrateTo(id)
+}
+
+func (m *Migrate) migrateTo(targetID string) error {
+	if err := m.ensureValid(); err != nil {
 		return err
 	}
 
@@ -72,17 +180,110 @@ func (m *Migrate) Migrate() error {
 		if err := m.runMigration(migration); err != nil {
 			return err
 		}
+		if targetID != "" && migration.ID == targetID {
+			break
+		}
 	}
 	return nil
 }
 
+// RollbackLast rolls back the last applied migration.
+func (m *Migrate) RollbackLast() error {
+	if err := m.ensureValid(); err != nil {
+		return err
+	}
+
+	applied, err := m.appliedMigrations()
+	if err != nil {
+		return err
+	}
+	if len(applied) == 0 {
+		return nil
+	}
+	return m.rollbackMigration(applied[len(applied)-1])
+}
+
+// RollbackTo rolls back, in reverse application order, every applied
+// migration up to but excluding the one identified by id.
+func (m *Migrate) RollbackTo(id string) error {
+	if err := m.ensureValid(); err != nil {
+		return err
+	}
+
+	applied, err := m.appliedMigrations()
+	if err != nil {
+		return err
+	}
+
+	found := false
+	for _, migration := range applied {
+		if migration.ID == id {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return ErrUnknownID{ID: id}
+	}
+
+	for i := len(applied) - 1; i >= 0; i-- {
+		if applied[i].ID == id {
+			break
+		}
+		if err := m.rollbackMigration(applied[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// appliedMigrations returns the configured migrations that have already run,
+// in application order.
+func (m *Migrate) appliedMigrations() ([]*Migration, error) {
+	var applied []*Migration
+	for _, migration := range m.migrations {
+		run, err := m.migrationDidRun(migration)
+		if err != nil {
+			return nil, err
+		}
+		if run {
+			applied = append(applied, migration)
+		}
+	}
+	return applied, nil
+}
+
+func (m *Migrate) rollbackMigration(migration *Migration) error {
+	if migration.Rollback == nil {
+		return ErrNoRollback{ID: migration.ID}
+	}
+
+	if !m.options.UseTransaction || migration.DisableTransaction {
+		if err := migration.Rollback(m.db); err != nil {
+			return err
+		}
+		return m.removeMigration(m.db, migration.ID)
+	}
+
+	if !m.dialectSupportsTransactionalDDL() {
+		return ErrTransactionalDDLUnsupported
+	}
+
+	return m.db.Transaction(func(tx *gorm.DB) error {
+		if err := migration.Rollback(tx); err != nil {
+			return err
+		}
+		return m.removeMigration(tx, migration.ID)
+	})
+}
+
 func (m *Migrate) runInitSchema() error {
 	if err := m.initSchema(m.db); err != nil {
 		return err
 	}
 
 	for _, migration := range m.migrations {
-		if err := m.insertMigration(migration.ID); err != nil {
+		if err := m.insertMigration(m.db, migration.ID, ""); err != nil {
 			return err
 		}
 	}
@@ -99,26 +300,196 @@ func (m *Migrate) runMigration(migration *Migration) error {
 	if err != nil {
 		return err
 	}
+	if run {
+		m.logger().Info(context.Background(), "gormmigrate: %s already applied, skipping", migration.ID)
+		return nil
+	}
+
+	m.logger().Info(context.Background(), "gormmigrate: applying %s", migration.ID)
+	start := time.Now()
 
-	if !run {
+	if err := m.applyMigration(migration); err != nil {
+		return err
+	}
+
+	m.logger().Info(context.Background(), "gormmigrate: applied %s in %s", migration.ID, time.Since(start))
+	return nil
+}
+
+func (m *Migrate) applyMigration(migration *Migration) error {
+	if !m.options.UseTransaction || migration.DisableTransaction {
 		if err := migration.Migrate(m.db); err != nil {
 			return err
 		}
+		return m.insertMigration(m.db, migration.ID, "")
+	}
+
+	if !m.dialectSupportsTransactionalDDL() {
+		return ErrTransactionalDDLUnsupported
+	}
 
-		if err := m.insertMigration(migration.ID); err != nil {
+	return m.db.Transaction(func(tx *gorm.DB) error {
+		if err := migration.Migrate(tx); err != nil {
 			return err
 		}
+		return m.insertMigration(tx, migration.ID, "")
+	})
+}
+
+// logger returns the configured Options.Logger, falling back to the DB
+// connection's own logger.
+func (m *Migrate) logger() logger.Interface {
+	if m.options.Logger != nil {
+		return m.options.Logger
+	}
+	return m.db.Logger
+}
+
+// Status reports, for every configured migration, whether it has been
+// applied and when. Applied-ness comes from row presence, not from
+// applied_at: a migration applied before applied_at tracking existed has no
+// timestamp to report but is still applied.
+func (m *Migrate) Status() ([]MigrationStatus, error) {
+	if err := m.ensureValid(); err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		applied, err := m.migrationDidRun(migration)
+		if err != nil {
+			return nil, err
+		}
+
+		var appliedAt *time.Time
+		if applied {
+			appliedAt, err = m.appliedAt(migration.ID)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		statuses = append(statuses, MigrationStatus{
+			ID:        migration.ID,
+			Applied:   applied,
+			AppliedAt: appliedAt,
+		})
+	}
+	return statuses, nil
+}
+
+// appliedAt returns the applied_at timestamp for id, or nil if the row has
+// none recorded (e.g. applied before the column was added by
+// upgradeMigrationTable). Callers must check applied-ness separately.
+func (m *Migrate) appliedAt(id string) (*time.Time, error) {
+	var appliedAt sql.NullTime
+	tx := m.db.Raw(fmt.Sprintf("SELECT %s FROM %s WHERE %s = ?", appliedAtColumnName, m.options.TableName, m.options.IDColumnName), id).Scan(&appliedAt)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	if !appliedAt.Valid {
+		return nil, nil
+	}
+	return &appliedAt.Time, nil
+}
+
+// dialectSupportsTransactionalDDL reports whether the connected dialect can
+// run DDL statements inside a transaction. MySQL/MariaDB implicitly commit
+// the current transaction on DDL, so wrapping it would be misleading.
+func (m *Migrate) dialectSupportsTransactionalDDL() bool {
+	switch m.db.Dialector.Name() {
+	case "mysql":
+		return false
+	default:
+		return true
+	}
+}
+
+// ensureValid makes sure the migrations table exists and the configured
+// migrations pass validate before anything runs against them.
+func (m *Migrate) ensureValid() error {
+	if err := m.createMigrationTableIfNotExists(); err != nil {
+		return err
+	}
+	return m.validate()
+}
+
+// validate checks the configured migrations for missing/duplicate/
+// out-of-order IDs and, when Options.ValidateUnknownMigrations is set, for
+// IDs recorded in the migrations table that are no longer configured.
+func (m *Migrate) validate() error {
+	seen := make(map[string]bool, len(m.migrations))
+	var lastID string
+	for _, migration := range m.migrations {
+		if len(migration.ID) == 0 {
+			return ErrMissingID
+		}
+		if seen[migration.ID] {
+			return ErrDuplicateID{ID: migration.ID}
+		}
+		seen[migration.ID] = true
+
+		if lastID != "" && migration.ID <= lastID {
+			return ErrOutOfOrderID
+		}
+		lastID = migration.ID
+	}
+
+	if !m.options.ValidateUnknownMigrations {
+		return nil
+	}
+	for _, vm := range m.versionedMigrations {
+		seen[fmt.Sprintf("%s:%s", vm.ID, phaseExpand)] = true
+		seen[fmt.Sprintf("%s:%s", vm.ID, phaseContract)] = true
+	}
+	return m.validateNoUnknownMigrations(seen)
+}
+
+// validateNoUnknownMigrations checks that every ID recorded in the
+// migrations table is either a configured Migration or a "<id>:expand" /
+// "<id>:contract" step of a configured VersionedMigration.
+func (m *Migrate) validateNoUnknownMigrations(known map[string]bool) error {
+	var ids []string
+	tx := m.db.Raw(fmt.Sprintf("SELECT %s FROM %s", m.options.IDColumnName, m.options.TableName)).Scan(&ids)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	for _, id := range ids {
+		if !known[id] {
+			return ErrUnknownMigrationInDB{ID: id}
+		}
 	}
 	return nil
 }
 
 func (m *Migrate) createMigrationTableIfNotExists() error {
-	exists := m.db.Migrator().HasTable(m.options.TableName)
-	if exists {
+	if m.db.Migrator().HasTable(m.options.TableName) {
+		return m.upgradeMigrationTable()
+	}
+
+	sql := fmt.Sprintf(
+		"CREATE TABLE %s (%s VARCHAR(255) PRIMARY KEY, %s TIMESTAMP, %s VARCHAR(20))",
+		m.options.TableName, m.options.IDColumnName, appliedAtColumnName, phaseColumnName,
+	)
+	tx := m.db.Exec(sql)
+	return tx.Error
+}
+
+// upgradeMigrationTable adds columns to a migrations table created by an
+// older version of this package.
+func (m *Migrate) upgradeMigrationTable() error {
+	if err := m.addColumnIfNotExists(appliedAtColumnName, "TIMESTAMP"); err != nil {
+		return err
+	}
+	return m.addColumnIfNotExists(phaseColumnName, "VARCHAR(20)")
+}
+
+func (m *Migrate) addColumnIfNotExists(name, sqlType string) error {
+	if m.db.Migrator().HasColumn(m.options.TableName, name) {
 		return nil
 	}
 
-	sql := fmt.Sprintf("CREATE TABLE %s (%s VARCHAR(255) PRIMARY KEY)", m.options.TableName, m.options.IDColumnName)
+	sql := fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", m.options.TableName, name, sqlType)
 	tx := m.db.Exec(sql)
 	return tx.Error
 }
@@ -136,9 +507,17 @@ func (m *Migrate) isFirstRun() bool {
 	return count == 0
 }
 
-func (m *Migrate) insertMigration(id string) error {
-	sql := fmt.Sprintf("INSERT INTO %s (%s) VALUES (?)", m.options.TableName, m.options.IDColumnName)
-	fmt.Printf("Execute %v with param %v", sql, id)
-	tx := m.db.Exec(sql, id)
+func (m *Migrate) insertMigration(db *gorm.DB, id, phase string) error {
+	sql := fmt.Sprintf(
+		"INSERT INTO %s (%s, %s, %s) VALUES (?, ?, ?)",
+		m.options.TableName, m.options.IDColumnName, appliedAtColumnName, phaseColumnName,
+	)
+	tx := db.Exec(sql, id, time.Now().UTC(), phase)
+	return tx.Error
+}
+
+func (m *Migrate) removeMigration(db *gorm.DB, id string) error {
+	sql := fmt.Sprintf("DELETE FROM %s WHERE %s = ?", m.options.TableName, m.options.IDColumnName)
+	tx := db.Exec(sql, id)
 	return tx.Error
 }
@@ -0,0 +1,130 @@
+package gormmigrate
+
+import (
+	"errors"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	return db
+}
+
+func testMigrations() []*Migration {
+	return []*Migration{
+		{
+			ID: "20230101000001_create_widgets",
+			Migrate: func(tx *gorm.DB) error {
+				return tx.Exec("CREATE TABLE widgets (id INTEGER PRIMARY KEY)").Error
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return tx.Exec("DROP TABLE widgets").Error
+			},
+		},
+		{
+			ID: "20230101000002_add_widgets_name",
+			Migrate: func(tx *gorm.DB) error {
+				return tx.Exec("ALTER TABLE widgets ADD COLUMN name TEXT").Error
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return tx.Exec("ALTER TABLE widgets DROP COLUMN name").Error
+			},
+		},
+		{
+			ID: "20230101000003_create_gadgets",
+			Migrate: func(tx *gorm.DB) error {
+				return tx.Exec("CREATE TABLE gadgets (id INTEGER PRIMARY KEY)").Error
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return tx.Exec("DROP TABLE gadgets").Error
+			},
+		},
+	}
+}
+
+func newTestMigrate(db *gorm.DB, migrations []*Migration) *Migrate {
+	return New(db, &Options{TableName: "_migrations", IDColumnName: "id"}, migrations)
+}
+
+func TestRollbackToOrder(t *testing.T) {
+	db := newTestDB(t)
+	migrations := testMigrations()
+	m := newTestMigrate(db, migrations)
+
+	if err := m.Migrate(); err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+
+	if err := m.RollbackTo(migrations[0].ID); err != nil {
+		t.Fatalf("RollbackTo: %v", err)
+	}
+
+	applied, err := m.appliedMigrations()
+	if err != nil {
+		t.Fatalf("appliedMigrations: %v", err)
+	}
+	if len(applied) != 1 || applied[0].ID != migrations[0].ID {
+		t.Fatalf("expected only %s to remain applied, got %v", migrations[0].ID, applied)
+	}
+
+	if db.Migrator().HasTable("gadgets") {
+		t.Fatal("expected gadgets table to be dropped by rollback")
+	}
+	if db.Migrator().HasColumn("widgets", "name") {
+		t.Fatal("expected widgets.name column to be dropped by rollback")
+	}
+	if !db.Migrator().HasTable("widgets") {
+		t.Fatal("expected widgets table, from the migration left applied, to still exist")
+	}
+}
+
+func TestRollbackToUnknownID(t *testing.T) {
+	db := newTestDB(t)
+	migrations := testMigrations()
+	m := newTestMigrate(db, migrations)
+
+	if err := m.Migrate(); err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+
+	err := m.RollbackTo("does-not-exist")
+	var unknownID ErrUnknownID
+	if !errors.As(err, &unknownID) {
+		t.Fatalf("expected ErrUnknownID, got %v", err)
+	}
+
+	applied, err := m.appliedMigrations()
+	if err != nil {
+		t.Fatalf("appliedMigrations: %v", err)
+	}
+	if len(applied) != len(migrations) {
+		t.Fatalf("expected RollbackTo with an unknown id to roll back nothing, got %d applied", len(applied))
+	}
+}
+
+func TestRollbackLast(t *testing.T) {
+	db := newTestDB(t)
+	migrations := testMigrations()
+	m := newTestMigrate(db, migrations)
+
+	if err := m.Migrate(); err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+	if err := m.RollbackLast(); err != nil {
+		t.Fatalf("RollbackLast: %v", err)
+	}
+
+	if db.Migrator().HasTable("gadgets") {
+		t.Fatal("expected gadgets table to be dropped by RollbackLast")
+	}
+	if !db.Migrator().HasColumn("widgets", "name") {
+		t.Fatal("expected widgets.name to remain, only the last migration should roll back")
+	}
+}
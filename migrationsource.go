@@ -0,0 +1,157 @@
+package gormmigrate
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// ErrMissingUpSQL is returned when a "<id>.down.sql" file has no matching
+// "<id>.up.sql", which would otherwise leave the migration with a nil
+// Migrate func.
+type ErrMissingUpSQL struct {
+	ID string
+}
+
+func (e ErrMissingUpSQL) Error() string {
+	return fmt.Sprintf("gormmigrate: migration %q has a .down.sql file but no matching .up.sql", e.ID)
+}
+
+// MigrationSource loads the migrations to run. It decouples Migrate from how
+// migrations are authored: as Go funcs, as raw SQL files on disk, or as SQL
+// files embedded into the binary.
+type MigrationSource interface {
+	Load() ([]*Migration, error)
+}
+
+// GoMigrationSource loads migrations the existing way, via a MigrationsStore
+// of hand-written Go Migration values.
+type GoMigrationSource struct {
+	Store MigrationsStore
+}
+
+func (s GoMigrationSource) Load() ([]*Migration, error) {
+	return s.Store.Migrations(), nil
+}
+
+// FileMigrationSource loads migrations from a directory of SQL files named
+// "YYYYMMDDHHMMSS_name.up.sql" / "YYYYMMDDHHMMSS_name.down.sql".
+type FileMigrationSource struct {
+	Dir string
+}
+
+func (s FileMigrationSource) Load() ([]*Migration, error) {
+	return loadSQLMigrations(os.DirFS(s.Dir), ".")
+}
+
+// EmbedFileMigrationSource is like FileMigrationSource but reads the SQL
+// files from an embed.FS, so migrations ship inside the binary and don't
+// require the source tree at runtime.
+type EmbedFileMigrationSource struct {
+	FS   embed.FS
+	Root string
+}
+
+func (s EmbedFileMigrationSource) Load() ([]*Migration, error) {
+	return loadSQLMigrations(s.FS, s.Root)
+}
+
+const (
+	upSQLSuffix   = ".up.sql"
+	downSQLSuffix = ".down.sql"
+)
+
+func loadSQLMigrations(fsys fs.FS, root string) ([]*Migration, error) {
+	entries, err := fs.ReadDir(fsys, root)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := map[string]*Migration{}
+	var ids []string
+	migrationFor := func(id string) *Migration {
+		mig, ok := byID[id]
+		if !ok {
+			mig = &Migration{ID: id}
+			byID[id] = mig
+			ids = append(ids, id)
+		}
+		return mig
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		name := entry.Name()
+
+		var id, suffix string
+		switch {
+		case strings.HasSuffix(name, upSQLSuffix):
+			id, suffix = strings.TrimSuffix(name, upSQLSuffix), upSQLSuffix
+		case strings.HasSuffix(name, downSQLSuffix):
+			id, suffix = strings.TrimSuffix(name, downSQLSuffix), downSQLSuffix
+		default:
+			continue
+		}
+
+		content, err := fs.ReadFile(fsys, path.Join(root, name))
+		if err != nil {
+			return nil, err
+		}
+		migrate := sqlMigrateFunc(content)
+
+		mig := migrationFor(id)
+		if suffix == upSQLSuffix {
+			mig.Migrate = migrate
+		} else {
+			mig.Rollback = migrate
+		}
+	}
+
+	sort.Strings(ids)
+	migrations := make([]*Migration, 0, len(ids))
+	for _, id := range ids {
+		mig := byID[id]
+		if mig.Migrate == nil {
+			return nil, ErrMissingUpSQL{ID: id}
+		}
+		migrations = append(migrations, mig)
+	}
+	return migrations, nil
+}
+
+func sqlMigrateFunc(content []byte) MigrateFunc {
+	statements := splitSQLStatements(string(content))
+	return func(tx *gorm.DB) error {
+		for _, statement := range statements {
+			if err := tx.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// splitSQLStatements splits on ";" and trims whitespace around each
+// statement. This is a naive split: it does not understand string literals
+// or dollar-quoted bodies, so a statement containing a semicolon inside one
+// (e.g. a Postgres `CREATE FUNCTION ... $$ ... ; ... $$`) will be cut into
+// multiple, broken statements, even when it is the only statement in its
+// file.
+func splitSQLStatements(sql string) []string {
+	var statements []string
+	for _, statement := range strings.Split(sql, ";") {
+		statement = strings.TrimSpace(statement)
+		if statement != "" {
+			statements = append(statements, statement)
+		}
+	}
+	return statements
+}
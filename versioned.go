@@ -0,0 +1,165 @@
+package gormmigrate
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	phaseExpand   = "expand"
+	phaseContract = "contract"
+)
+
+// ErrUnknownVersion is returned by MigrateContract when no VersionedMigration
+// is configured for the requested version.
+type ErrUnknownVersion struct {
+	Version string
+}
+
+func (e ErrUnknownVersion) Error() string {
+	return fmt.Sprintf("gormmigrate: no versioned migration for version %q", e.Version)
+}
+
+// VersionedMigration describes a backward-compatible schema change as an
+// expand/contract pair, following pg-roll's model: Expand applies additive
+// changes (new columns/tables) that old and new application instances can
+// both work against, and Contract drops the old shape once every instance
+// has moved to the new one.
+type VersionedMigration struct {
+	// ID is the migration identifier, same convention as Migration.ID.
+	ID string
+	// Version names this expand/contract pair, used to build the
+	// compatibility view schema "<VersionedSchemaPrefix>_v<Version>".
+	Version string
+	// Table is the table the compatibility view wraps. Leave empty to skip
+	// view creation (e.g. when Expand only adds a table, not a column).
+	Table string
+	// Columns maps old column name to the new column/expression that
+	// produces it, and is used to build the compatibility view exposed by
+	// MigrateExpand for Postgres.
+	Columns map[string]string
+	// Expand applies the additive, backward-compatible schema change.
+	Expand MigrateFunc
+	// Contract drops the old shape. It runs once every consumer has moved
+	// to the new schema.
+	Contract MigrateFunc
+}
+
+// MigrateExpand runs the expand phase of every configured VersionedMigration
+// that hasn't run yet, and, on Postgres, (re)creates its compatibility view
+// so that application instances still reading the old column names keep
+// working.
+func (m *Migrate) MigrateExpand() error {
+	if err := m.createMigrationTableIfNotExists(); err != nil {
+		return err
+	}
+
+	for _, vm := range m.versionedMigrations {
+		if err := m.runVersionedPhase(vm, phaseExpand, vm.Expand); err != nil {
+			return err
+		}
+		if err := m.createVersionedView(vm); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MigrateContract drops the compatibility view for version and runs its
+// Contract phase.
+func (m *Migrate) MigrateContract(version string) error {
+	if err := m.createMigrationTableIfNotExists(); err != nil {
+		return err
+	}
+
+	vm := m.versionedMigrationByVersion(version)
+	if vm == nil {
+		return ErrUnknownVersion{Version: version}
+	}
+
+	if err := m.dropVersionedView(vm); err != nil {
+		return err
+	}
+	return m.runVersionedPhase(vm, phaseContract, vm.Contract)
+}
+
+func (m *Migrate) versionedMigrationByVersion(version string) *VersionedMigration {
+	for _, vm := range m.versionedMigrations {
+		if vm.Version == version {
+			return vm
+		}
+	}
+	return nil
+}
+
+// runVersionedPhase tracks expand/contract as independent, idempotent steps,
+// keyed as "<id>:<phase>" in the same migrations table regular migrations
+// use, with phase also recorded in its own column for reporting.
+func (m *Migrate) runVersionedPhase(vm *VersionedMigration, phase string, fn MigrateFunc) error {
+	if fn == nil {
+		return nil
+	}
+
+	step := &Migration{ID: fmt.Sprintf("%s:%s", vm.ID, phase)}
+	run, err := m.migrationDidRun(step)
+	if err != nil {
+		return err
+	}
+	if run {
+		m.logger().Info(context.Background(), "gormmigrate: %s already applied, skipping", step.ID)
+		return nil
+	}
+
+	m.logger().Info(context.Background(), "gormmigrate: applying %s", step.ID)
+	if err := fn(m.db); err != nil {
+		return err
+	}
+	return m.insertMigration(m.db, step.ID, phase)
+}
+
+func (m *Migrate) createVersionedView(vm *VersionedMigration) error {
+	if vm.Table == "" || len(vm.Columns) == 0 || m.db.Dialector.Name() != "postgres" {
+		return nil
+	}
+
+	schema := m.versionedSchemaName(vm)
+	if err := m.db.Exec(fmt.Sprintf("CREATE SCHEMA IF NOT EXISTS %s", schema)).Error; err != nil {
+		return err
+	}
+
+	columns := make([]string, 0, len(vm.Columns))
+	for oldName := range vm.Columns {
+		columns = append(columns, oldName)
+	}
+	sort.Strings(columns)
+
+	selects := make([]string, 0, len(columns))
+	for _, oldName := range columns {
+		selects = append(selects, fmt.Sprintf("%s AS %s", vm.Columns[oldName], oldName))
+	}
+
+	sql := fmt.Sprintf(
+		"CREATE OR REPLACE VIEW %s.%s AS SELECT %s FROM %s",
+		schema, vm.Table, strings.Join(selects, ", "), vm.Table,
+	)
+	return m.db.Exec(sql).Error
+}
+
+func (m *Migrate) dropVersionedView(vm *VersionedMigration) error {
+	if vm.Table == "" || m.db.Dialector.Name() != "postgres" {
+		return nil
+	}
+
+	schema := m.versionedSchemaName(vm)
+	return m.db.Exec(fmt.Sprintf("DROP VIEW IF EXISTS %s.%s", schema, vm.Table)).Error
+}
+
+func (m *Migrate) versionedSchemaName(vm *VersionedMigration) string {
+	prefix := m.options.VersionedSchemaPrefix
+	if prefix == "" {
+		prefix = m.options.TableName
+	}
+	return fmt.Sprintf("%s_v%s", prefix, vm.Version)
+}
@@ -0,0 +1,66 @@
+package gormmigrate
+
+import (
+	"errors"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func TestMigrateExpandContractPhaseTracking(t *testing.T) {
+	db := newTestDB(t)
+	var expandCalls, contractCalls int
+
+	vm := &VersionedMigration{
+		ID:      "20230101000010_split_name",
+		Version: "2",
+		Expand: func(tx *gorm.DB) error {
+			expandCalls++
+			return tx.Exec("CREATE TABLE accounts (id INTEGER PRIMARY KEY)").Error
+		},
+		Contract: func(tx *gorm.DB) error {
+			contractCalls++
+			return tx.Exec("DROP TABLE accounts").Error
+		},
+	}
+
+	m := newTestMigrate(db, nil)
+	m.VersionedMigrations([]*VersionedMigration{vm})
+
+	if err := m.MigrateExpand(); err != nil {
+		t.Fatalf("MigrateExpand: %v", err)
+	}
+	if err := m.MigrateExpand(); err != nil {
+		t.Fatalf("second MigrateExpand: %v", err)
+	}
+	if expandCalls != 1 {
+		t.Fatalf("expected Expand to run once, ran %d times", expandCalls)
+	}
+	if !db.Migrator().HasTable("accounts") {
+		t.Fatal("expected Expand to have created the accounts table")
+	}
+
+	if err := m.MigrateContract(vm.Version); err != nil {
+		t.Fatalf("MigrateContract: %v", err)
+	}
+	if err := m.MigrateContract(vm.Version); err != nil {
+		t.Fatalf("second MigrateContract: %v", err)
+	}
+	if contractCalls != 1 {
+		t.Fatalf("expected Contract to run once, ran %d times", contractCalls)
+	}
+	if db.Migrator().HasTable("accounts") {
+		t.Fatal("expected Contract to have dropped the accounts table")
+	}
+}
+
+func TestMigrateContractUnknownVersion(t *testing.T) {
+	db := newTestDB(t)
+	m := newTestMigrate(db, nil)
+
+	err := m.MigrateContract("does-not-exist")
+	var unknownVersion ErrUnknownVersion
+	if !errors.As(err, &unknownVersion) {
+		t.Fatalf("expected ErrUnknownVersion, got %v", err)
+	}
+}